@@ -0,0 +1,276 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =========================================================================
+// 16. Módulo de Federación (`federation` package / section)
+//    Publica cada reproducción completada como una Activity de
+//    ActivityStreams ("Watch") en el outbox del usuario, y procesa
+//    Activities entrantes de otras instancias en una bandeja compartida,
+//    verificando su firma HTTP. Las Watch entrantes alimentan al
+//    recomendador como una señal de popularidad entre instancias.
+// =========================================================================
+
+const activityStreamsContext = "https://www.w3.org/ns/activitystreams"
+
+// Activity es una Activity de ActivityStreams simplificada al único tipo
+// que esta plataforma produce y consume: "Watch".
+type Activity struct {
+	Context   []string  `json:"@context"`
+	Type      string    `json:"type"`
+	Actor     string    `json:"actor"`
+	Object    string    `json:"object"`
+	Published time.Time `json:"published"`
+}
+
+// NewWatchActivity construye la Activity publicada cuando actorIRI termina
+// de reproducir el stream identificado por objectIRI.
+func NewWatchActivity(actorIRI, objectIRI string) Activity {
+	return Activity{
+		Context:   []string{activityStreamsContext},
+		Type:      "Watch",
+		Actor:     actorIRI,
+		Object:    objectIRI,
+		Published: time.Now(),
+	}
+}
+
+// MarshalJSONLD serializa la Activity como JSON-LD, listo para publicarse
+// en el outbox de un actor o enviarse a la bandeja de otra instancia.
+func (a Activity) MarshalJSONLD() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// Federation mantiene los outboxes locales, la bandeja de entrada
+// compartida y las suscripciones ("Follow") a actores remotos de una
+// instancia. localInstanceBaseIRI se usa para construir los IRIs de
+// actores y streams propios de esta instancia.
+type Federation struct {
+	mu sync.RWMutex
+
+	localInstanceBaseIRI string
+	outboxes             map[string][]Activity // localUserID -> actividades publicadas
+	inbox                []Activity            // bandeja de entrada compartida
+	follows              map[string][]string   // localUserID -> IRIs de actores remotos seguidos
+	crossPopularity      map[string]int        // streamIRI -> veces reportado como visto por otras instancias
+}
+
+// NewFederation crea una Federation para una instancia cuyo IRI base es
+// baseIRI (ej. "https://streaming.example.com").
+func NewFederation(baseIRI string) *Federation {
+	return &Federation{
+		localInstanceBaseIRI: strings.TrimSuffix(baseIRI, "/"),
+		outboxes:             make(map[string][]Activity),
+		follows:              make(map[string][]string),
+		crossPopularity:      make(map[string]int),
+	}
+}
+
+// ActorIRI devuelve el IRI de actor ActivityPub de un usuario local.
+func (f *Federation) ActorIRI(localUserID string) string {
+	return fmt.Sprintf("%s/users/%s", f.localInstanceBaseIRI, localUserID)
+}
+
+// StreamIRI devuelve el IRI de objeto ActivityPub de un stream local.
+func (f *Federation) StreamIRI(streamID string) string {
+	return fmt.Sprintf("%s/streams/%s", f.localInstanceBaseIRI, streamID)
+}
+
+// Publish agrega una Activity "Watch" al outbox del usuario que terminó de
+// ver stream, y la devuelve para que el llamador pueda reenviarla a los
+// seguidores remotos si así lo desea.
+func (f *Federation) Publish(user *User, stream *Stream) Activity {
+	activity := NewWatchActivity(f.ActorIRI(user.GetID()), f.StreamIRI(stream.GetID()))
+
+	f.mu.Lock()
+	f.outboxes[user.GetID()] = append(f.outboxes[user.GetID()], activity)
+	f.mu.Unlock()
+
+	return activity
+}
+
+// Outbox devuelve una copia de las Activities publicadas por un usuario local.
+func (f *Federation) Outbox(localUserID string) []Activity {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	activities := f.outboxes[localUserID]
+	out := make([]Activity, len(activities))
+	copy(out, activities)
+	return out
+}
+
+// Follow suscribe a un usuario local al feed de visualización de un actor
+// remoto identificado por su IRI.
+func (f *Federation) Follow(localUserID, remoteActorIRI string) error {
+	if localUserID == "" || remoteActorIRI == "" {
+		return ErrInvalidInput
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.follows[localUserID] = append(f.follows[localUserID], remoteActorIRI)
+	return nil
+}
+
+// Following devuelve los IRIs de actores remotos que un usuario local sigue.
+func (f *Federation) Following(localUserID string) []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	following := f.follows[localUserID]
+	out := make([]string, len(following))
+	copy(out, following)
+	return out
+}
+
+// CrossInstancePopularity devuelve cuántas veces una Activity "Watch"
+// entrante reportó visualizaciones del stream identificado por streamIRI.
+func (f *Federation) CrossInstancePopularity(streamIRI string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.crossPopularity[streamIRI]
+}
+
+// CrossInstancePopularityForStream es un atajo de CrossInstancePopularity
+// para un stream local, usado por el recomendador.
+func (f *Federation) CrossInstancePopularityForStream(streamID string) int {
+	return f.CrossInstancePopularity(f.StreamIRI(streamID))
+}
+
+// recordInbound agrega una Activity entrante a la bandeja compartida y, si
+// es una "Watch", incrementa la señal de popularidad entre instancias.
+func (f *Federation) recordInbound(activity Activity) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.inbox = append(f.inbox, activity)
+	if activity.Type == "Watch" {
+		f.crossPopularity[activity.Object]++
+	}
+}
+
+// =========================================================================
+// 16.1 InboxProcessor: recepción y verificación de Activities remotas
+// =========================================================================
+
+// InboxProcessor atiende el POST a la bandeja de entrada compartida,
+// verifica la firma HTTP del remitente y, si es válida, entrega la
+// Activity a Federation.
+type InboxProcessor struct {
+	federation *Federation
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey // actorIRI -> clave pública registrada fuera de banda
+}
+
+// NewInboxProcessor crea un InboxProcessor respaldado por federation.
+func NewInboxProcessor(federation *Federation) *InboxProcessor {
+	return &InboxProcessor{federation: federation, keys: make(map[string]*rsa.PublicKey)}
+}
+
+// RegisterActorKey asocia la clave pública de un actor remoto, usada para
+// verificar la firma HTTP de sus Activities entrantes.
+func (ip *InboxProcessor) RegisterActorKey(actorIRI string, pub *rsa.PublicKey) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	ip.keys[actorIRI] = pub
+}
+
+// ServeHTTP procesa un POST entrante a la bandeja de entrada compartida.
+func (ip *InboxProcessor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "no se pudo leer el cuerpo de la petición", http.StatusBadRequest)
+		return
+	}
+
+	var activity Activity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		http.Error(w, "cuerpo JSON-LD inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := ip.verifySignature(r, activity.Actor); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ip.federation.recordInbound(activity)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verifySignature valida la cabecera HTTP Signature (RFC draft-cavage)
+// enviada por actorIRI contra su clave pública registrada.
+func (ip *InboxProcessor) verifySignature(r *http.Request, actorIRI string) error {
+	ip.mu.RLock()
+	pub, ok := ip.keys[actorIRI]
+	ip.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: clave pública desconocida para el actor '%s'", ErrUnauthorized, actorIRI)
+	}
+
+	sigHeader := r.Header.Get("Signature")
+	if sigHeader == "" {
+		return fmt.Errorf("%w: falta la cabecera Signature", ErrUnauthorized)
+	}
+	params := parseSignatureHeader(sigHeader)
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("%w: firma mal codificada", ErrUnauthorized)
+	}
+
+	signingString := buildSigningString(r, params["headers"])
+	hashed := sha256.Sum256([]byte(signingString))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], signature); err != nil {
+		return fmt.Errorf("%w: verificación de firma HTTP fallida: %v", ErrUnauthorized, err)
+	}
+	return nil
+}
+
+// parseSignatureHeader separa los pares clave="valor" de una cabecera
+// Signature en un mapa, según el formato de draft-cavage-http-signatures.
+func parseSignatureHeader(header string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[kv[0]] = strings.Trim(kv[1], `"`)
+	}
+	return params
+}
+
+// buildSigningString reconstruye el signing string sobre el que se validó
+// la firma, a partir de los headers listados en el parámetro "headers".
+func buildSigningString(r *http.Request, headersParam string) string {
+	headerNames := strings.Fields(headersParam)
+	if len(headerNames) == 0 {
+		headerNames = []string{"(request-target)", "host", "date"}
+	}
+
+	lines := make([]string, 0, len(headerNames))
+	for _, name := range headerNames {
+		if name == "(request-target)" {
+			lines = append(lines, fmt.Sprintf("(request-target): %s %s", strings.ToLower(r.Method), r.URL.RequestURI()))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", name, r.Header.Get(name)))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv" // Necesario para convertir enteros a string para IDs
+	"sync"    // Protege el estado mutable compartido entre reproducciones concurrentes
 	"time"    // Para simular duraciones y marcas de tiempo
 )
 
@@ -15,11 +17,14 @@ import (
 
 // Stream representa un elemento de contenido de streaming.
 type Stream struct {
-	id          string // id del stream (ej. "movie-1", "series-ep-5") - no exportado
-	title       string // Título del contenido - no exportado
-	genre       string // Género (ej. "Acción", "Comedia") - no exportado
-	durationMin int    // Duración en minutos - no exportado
-	url         string // URL de reproducción - no exportado
+	id                string    // id del stream (ej. "movie-1", "series-ep-5") - no exportado
+	title             string    // Título del contenido - no exportado
+	genre             string    // Género (ej. "Acción", "Comedia") - no exportado
+	durationMin       int       // Duración en minutos - no exportado
+	url               string    // URL de reproducción - no exportado
+	addedAt           time.Time // Momento en que el contenido fue dado de alta - no exportado
+	availableProfiles []Profile // Perfiles de transcodificación disponibles, ascendente - no exportado
+	info              MediaInfo // Metadatos obtenidos por MediaProbe - no exportado
 }
 
 // NewStream es una función constructora para crear una nueva instancia de Stream.
@@ -31,6 +36,7 @@ func NewStream(id, title, genre, url string, durationMin int) *Stream {
 		genre:       genre,
 		durationMin: durationMin,
 		url:         url,
+		addedAt:     time.Now(),
 	}
 }
 
@@ -50,22 +56,60 @@ func (s *Stream) GetDurationMin() int {
 func (s *Stream) GetURL() string {
 	return s.url
 }
+func (s *Stream) GetAddedAt() time.Time {
+	return s.addedAt
+}
+
+// GetAvailableProfiles devuelve una copia de los perfiles de
+// transcodificación disponibles para este stream, ordenados ascendentemente.
+func (s *Stream) GetAvailableProfiles() []Profile {
+	profiles := make([]Profile, len(s.availableProfiles))
+	copy(profiles, s.availableProfiles)
+	return profiles
+}
+
+// setAvailableProfiles asigna los perfiles de transcodificación disponibles.
+// No exportado: lo usan AddContent al dar de alta un stream y los backends
+// de almacenamiento al reconstruirlo.
+func (s *Stream) setAvailableProfiles(profiles []Profile) {
+	s.availableProfiles = profiles
+}
+
+// GetInfo devuelve los metadatos técnicos obtenidos por MediaProbe.
+func (s *Stream) GetInfo() MediaInfo {
+	return s.info
+}
 
-// User representa un usuario del sistema de streaming.
+// setInfo asigna los metadatos técnicos del stream. No exportado: lo usan
+// AddContent tras invocar MediaProbe y los backends de almacenamiento al
+// reconstruir el stream.
+func (s *Stream) setInfo(info MediaInfo) {
+	s.info = info
+}
+
+// User representa un usuario del sistema de streaming. SessionManager
+// permite varias reproducciones concurrentes del mismo usuario (hasta el
+// límite de su plan), así que mu protege subscription/watchHistory/
+// currentStream contra esas lecturas y escrituras concurrentes.
 type User struct {
-	id            string   // ID del usuario - no exportado
-	username      string   // Nombre de usuario - no exportado
-	subscription  string   // Tipo de suscripción (ej. "Premium", "Basic") - no exportado
-	watchHistory  []string // Slice de IDs de streams vistos - no exportado
-	currentStream *Stream  // Stream actualmente en reproducción - no exportado
+	mu sync.RWMutex
+
+	id            string           // ID del usuario - no exportado
+	username      string           // Nombre de usuario - no exportado
+	subscription  SubscriptionTier // Nivel de suscripción (Free, Basic, Premium) - no exportado
+	watchHistory  []string         // Slice de IDs de streams vistos - no exportado
+	currentStream *Stream          // Stream actualmente en reproducción - no exportado
 }
 
 // NewUser es una función constructora para crear una nueva instancia de User.
+// subscription se recibe como string para no acoplar a los llamadores al
+// tipo SubscriptionTier; la validación ocurre en UserStore.AddUser, igual
+// que isValidGenre valida el género de un Stream en AddStream.
 func NewUser(id, username, subscription string) *User {
 	return &User{
 		id:           id,
 		username:     username,
-		subscription: subscription,
+		subscription: SubscriptionTier(subscription),
 		watchHistory: []string{}, // Inicializa el historial como un slice vacío
 	}
 }
@@ -78,18 +122,40 @@ func (u *User) GetUsername() string {
 	return u.username
 }
 func (u *User) GetSubscription() string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return string(u.subscription)
+}
+
+// GetSubscriptionTier devuelve el nivel de suscripción tipado del usuario.
+func (u *User) GetSubscriptionTier() SubscriptionTier {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
 	return u.subscription
 }
 
+// SetSubscriptionTier cambia el nivel de suscripción del usuario. Usado por
+// StreamingPlatform.UpgradeSubscription; no valida el valor, responsabilidad
+// que recae en el llamador (igual que SetCurrentStream no valida el stream).
+func (u *User) SetSubscriptionTier(tier SubscriptionTier) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.subscription = tier
+}
+
 // AddToWatchHistory añade un stream al historial de visualización del usuario.
 // Ejemplo de método que modifica el estado interno de forma controlada.
 func (u *User) AddToWatchHistory(streamID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
 	u.watchHistory = append(u.watchHistory, streamID)
 }
 
 // GetWatchHistory devuelve una copia del historial para mantener la encapsulación.
 // Esto evita modificaciones externas directas del slice interno.
 func (u *User) GetWatchHistory() []string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
 	// Devuelve una copia para evitar que el slice interno sea modificado directamente desde fuera
 	historyCopy := make([]string, len(u.watchHistory))
 	copy(historyCopy, u.watchHistory)
@@ -98,11 +164,15 @@ func (u *User) GetWatchHistory() []string {
 
 // SetCurrentStream establece el stream actual que el usuario está viendo.
 func (u *User) SetCurrentStream(s *Stream) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
 	u.currentStream = s
 }
 
 // GetCurrentStream devuelve el stream actual que el usuario está viendo.
 func (u *User) GetCurrentStream() *Stream {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
 	return u.currentStream
 }
 
@@ -138,6 +208,14 @@ type StreamStore interface {
 	AddStream(stream *Stream) error
 	GetStreamByID(id string) (*Stream, error)
 	GetAllStreams() []*Stream
+	// ListStreams devuelve una página de streams, útil cuando el catálogo
+	// crece demasiado para traerlo completo con GetAllStreams.
+	ListStreams(offset, limit int) []*Stream
+
+	// RecordView incrementa el contador de popularidad global de un stream.
+	RecordView(streamID string)
+	// Popularity devuelve cuántas veces se ha visto un stream en toda la plataforma.
+	Popularity(streamID string) int
 }
 
 // UserStore define el contrato para almacenar y recuperar usuarios.
@@ -145,6 +223,12 @@ type UserStore interface {
 	AddUser(user *User) error
 	GetUserByID(id string) (*User, error)
 	GetAllUsers() []*User
+	// ListUsers devuelve una página de usuarios, equivalente paginado de
+	// GetAllUsers para backends con tablas grandes.
+	ListUsers(offset, limit int) []*User
+	// UpdateUser persiste cambios sobre un usuario ya existente (ej.
+	// suscripción actualizada, nuevas entradas en el historial).
+	UpdateUser(user *User) error
 }
 
 // =========================================================================
@@ -185,17 +269,23 @@ func simulatePlaybackDuration(durationMin int) {
 // =========================================================================
 
 // InMemoryStreamStore implementa StreamStore utilizando un map en memoria.
-// Los campos son no exportados para encapsulación.
+// Los campos son no exportados para encapsulación. mu protege streams/
+// popularity de llamadas concurrentes (ej. varias reproducciones del mismo
+// usuario vía SessionManager, cada una registrando su propia vista).
 type InMemoryStreamStore struct {
-	streams map[string]*Stream // map[ID del stream]Stream
-	nextID  int                // Contador para generar IDs
+	mu sync.Mutex
+
+	streams    map[string]*Stream // map[ID del stream]Stream
+	nextID     int                // Contador para generar IDs
+	popularity map[string]int     // map[ID del stream]veces reproducido, para el motor de recomendación
 }
 
 // NewInMemoryStreamStore crea una nueva instancia del almacén de streams en memoria.
 func NewInMemoryStreamStore() *InMemoryStreamStore {
 	return &InMemoryStreamStore{
-		streams: make(map[string]*Stream),
-		nextID:  0,
+		streams:    make(map[string]*Stream),
+		nextID:     0,
+		popularity: make(map[string]int),
 	}
 }
 
@@ -209,6 +299,9 @@ func (s *InMemoryStreamStore) AddStream(stream *Stream) error {
 		return fmt.Errorf("%w: genero '%s' no valido", ErrInvalidInput, stream.GetGenre())
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Como el ID viene del constructor, verificamos si ya existe.
 	// En este diseño, NewStream ya provee el ID, así que simplemente lo usamos.
 	// Si quisiéramos auto-generar aquí, usaríamos generateNextID y lo asignaríamos al stream.
@@ -224,6 +317,8 @@ func (s *InMemoryStreamStore) AddStream(stream *Stream) error {
 // GetStreamByID recupera un stream por su ID.
 // Retorna ErrStreamNotFound si el stream no existe.
 func (s *InMemoryStreamStore) GetStreamByID(id string) (*Stream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	stream, ok := s.streams[id]
 	if !ok {
 		return nil, ErrStreamNotFound
@@ -234,6 +329,8 @@ func (s *InMemoryStreamStore) GetStreamByID(id string) (*Stream, error) {
 // GetAllStreams devuelve todos los streams almacenados.
 // Retorna un slice de punteros a Stream.
 func (s *InMemoryStreamStore) GetAllStreams() []*Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	// Devuelve una copia del slice de streams para mantener la encapsulación.
 	// No se modifica el map original directamente.
 	allStreams := make([]*Stream, 0, len(s.streams))
@@ -243,13 +340,47 @@ func (s *InMemoryStreamStore) GetAllStreams() []*Stream {
 	return allStreams
 }
 
+// ListStreams devuelve una página ordenada por ID, de tamaño `limit` a
+// partir de `offset`. Si offset está fuera de rango devuelve una página vacía.
+func (s *InMemoryStreamStore) ListStreams(offset, limit int) []*Stream {
+	all := s.GetAllStreams()
+	sort.Slice(all, func(i, j int) bool { return all[i].GetID() < all[j].GetID() })
+
+	if offset < 0 || offset >= len(all) || limit <= 0 {
+		return []*Stream{}
+	}
+	end := offset + limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[offset:end]
+}
+
+// RecordView incrementa el contador global de reproducciones de un stream.
+func (s *InMemoryStreamStore) RecordView(streamID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.popularity[streamID]++
+}
+
+// Popularity devuelve el número de reproducciones registradas para un stream.
+func (s *InMemoryStreamStore) Popularity(streamID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.popularity[streamID]
+}
+
 // =========================================================================
 // 6. Lógica del Módulo de Usuario (`user` package / section)
 //    Uso de maps para un acceso eficiente por ID de usuario.
 // =========================================================================
 
-// InMemoryUserStore implementa UserStore utilizando un map en memoria.
+// InMemoryUserStore implementa UserStore utilizando un map en memoria. mu
+// protege users de llamadas concurrentes (ej. UpdateUser al finalizar
+// reproducciones concurrentes del mismo o distintos usuarios).
 type InMemoryUserStore struct {
+	mu sync.Mutex
+
 	users  map[string]*User // map[ID del usuario]User
 	nextID int              // Contador para generar IDs
 }
@@ -267,6 +398,13 @@ func (us *InMemoryUserStore) AddUser(user *User) error {
 	if user == nil || user.GetUsername() == "" || user.GetSubscription() == "" {
 		return ErrInvalidInput
 	}
+	if !isValidSubscriptionTier(user.GetSubscriptionTier()) {
+		return fmt.Errorf("%w: nivel de suscripción '%s' no válido", ErrInvalidInput, user.GetSubscription())
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
 	if _, exists := us.users[user.GetID()]; exists {
 		return fmt.Errorf("usuario con ID '%s' ya existe", user.GetID())
 	}
@@ -277,6 +415,8 @@ func (us *InMemoryUserStore) AddUser(user *User) error {
 
 // GetUserByID recupera un usuario por su ID.
 func (us *InMemoryUserStore) GetUserByID(id string) (*User, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
 	user, ok := us.users[id]
 	if !ok {
 		return nil, ErrUserNotFound
@@ -284,8 +424,29 @@ func (us *InMemoryUserStore) GetUserByID(id string) (*User, error) {
 	return user, nil
 }
 
+// UpdateUser persiste los cambios de un usuario ya existente. Como
+// InMemoryUserStore guarda el puntero directamente, los cambios ya son
+// visibles antes de llamar a este método; se mantiene por simetría con el
+// resto de los backends de UserStore.
+func (us *InMemoryUserStore) UpdateUser(user *User) error {
+	if user == nil {
+		return ErrInvalidInput
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	if _, ok := us.users[user.GetID()]; !ok {
+		return ErrUserNotFound
+	}
+	us.users[user.GetID()] = user
+	return nil
+}
+
 // GetAllUsers devuelve todos los usuarios registrados.
 func (us *InMemoryUserStore) GetAllUsers() []*User {
+	us.mu.Lock()
+	defer us.mu.Unlock()
 	allUsers := make([]*User, 0, len(us.users))
 	for _, user := range us.users {
 		allUsers = append(allUsers, user)
@@ -293,6 +454,22 @@ func (us *InMemoryUserStore) GetAllUsers() []*User {
 	return allUsers
 }
 
+// ListUsers devuelve una página ordenada por ID, de tamaño `limit` a partir
+// de `offset`. Si offset está fuera de rango devuelve una página vacía.
+func (us *InMemoryUserStore) ListUsers(offset, limit int) []*User {
+	all := us.GetAllUsers()
+	sort.Slice(all, func(i, j int) bool { return all[i].GetID() < all[j].GetID() })
+
+	if offset < 0 || offset >= len(all) || limit <= 0 {
+		return []*User{}
+	}
+	end := offset + limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[offset:end]
+}
+
 // =========================================================================
 // 7. Lógica del Módulo de Reproducción (`playback` package / section)
 // =========================================================================
@@ -300,6 +477,13 @@ func (us *InMemoryUserStore) GetAllUsers() []*User {
 // PlayStream simula la reproducción de un stream para un usuario.
 // Demuestra manejo de errores e interacción con objetos Stream y User.
 func PlayStream(user *User, stream Playable) error {
+	return playStreamWithHub(user, stream, nil)
+}
+
+// playStreamWithHub es la implementación real de PlayStream. Si hub no es
+// nil, emite un evento playback_progress a la mitad de la reproducción,
+// además de los eventos started/finished publicados por el llamador.
+func playStreamWithHub(user *User, stream Playable, hub *StreamingHub) error {
 	if user == nil {
 		return ErrUserNotFound
 	}
@@ -312,7 +496,16 @@ func PlayStream(user *User, stream Playable) error {
 	fmt.Printf("URL: %s\n", stream.GetURL())
 
 	user.SetCurrentStream(stream.(*Stream)) // Asignar el stream actual al usuario
-	simulatePlaybackDuration(stream.GetDurationMin())
+
+	if hub != nil {
+		halfway := stream.GetDurationMin() / 2
+		simulatePlaybackDuration(halfway)
+		hub.Publish(user.GetID(), Event{Type: EventPlaybackProgress, UserID: user.GetID(), StreamID: stream.(*Stream).GetID(), Payload: halfway})
+		simulatePlaybackDuration(stream.GetDurationMin() - halfway)
+	} else {
+		simulatePlaybackDuration(stream.GetDurationMin())
+	}
+
 	user.AddToWatchHistory(stream.(*Stream).GetID()) // Añadir al historial
 	user.SetCurrentStream(nil)                       // Limpiar stream actual al finalizar
 
@@ -330,6 +523,11 @@ func PlayStream(user *User, stream Playable) error {
 type StreamingPlatform struct {
 	streamStore StreamStore
 	userStore   UserStore
+	hub         *StreamingHub        // opcional: si está presente, se publican eventos en tiempo real
+	recommender RecommendationEngine // estrategia de ranking usada por GetFeed
+	sessions    *SessionManager      // aplica el límite de streams concurrentes por plan
+	probe       MediaProbe           // analiza archivos para poblar Stream.Info en AddContent
+	federation  *Federation          // opcional: publica Watch activities a otras instancias
 }
 
 // NewStreamingPlatform crea una nueva instancia de la plataforma de streaming.
@@ -337,7 +535,70 @@ func NewStreamingPlatform(ss StreamStore, us UserStore) *StreamingPlatform {
 	return &StreamingPlatform{
 		streamStore: ss,
 		userStore:   us,
+		recommender: NewCollaborativeRecommender(ss),
+		sessions:    NewSessionManager(),
+		probe:       NewFFProbe(),
+	}
+}
+
+// SetProbe reemplaza el MediaProbe usado por AddContent. Útil para pruebas
+// o para backends de análisis distintos de ffprobe.
+func (p *StreamingPlatform) SetProbe(probe MediaProbe) {
+	p.probe = probe
+}
+
+// SetFederation conecta una Federation a la plataforma para que
+// UserWatchStream publique cada reproducción completada como una Watch
+// activity, y para que el recomendador incorpore la señal de popularidad
+// entre instancias. Es opcional: sin ella, la plataforma sigue siendo de
+// una sola instancia.
+func (p *StreamingPlatform) SetFederation(federation *Federation) {
+	p.federation = federation
+	if cr, ok := p.recommender.(*CollaborativeRecommender); ok {
+		cr.SetFederation(federation)
+	}
+}
+
+// Follow suscribe a un usuario local al feed de visualización de un actor
+// remoto de otra instancia. Requiere que la plataforma tenga una Federation
+// configurada vía SetFederation.
+func (p *StreamingPlatform) Follow(localUserID, remoteActorIRI string) error {
+	if p.federation == nil {
+		return fmt.Errorf("%w: la federación no está configurada en esta plataforma", ErrInvalidInput)
+	}
+	if _, err := p.userStore.GetUserByID(localUserID); err != nil {
+		return err
+	}
+	return p.federation.Follow(localUserID, remoteActorIRI)
+}
+
+// GetManifest genera el manifiesto de streaming (HLS o DASH) de un stream,
+// listo para servirse tal cual al reproductor del cliente.
+func (p *StreamingPlatform) GetManifest(streamID string, format ManifestFormat) ([]byte, error) {
+	stream, err := p.streamStore.GetStreamByID(streamID)
+	if err != nil {
+		return nil, err
 	}
+	return GenerateManifest(stream, format)
+}
+
+// GetFeed devuelve el feed de recomendaciones personalizado de un usuario,
+// limitado a `limit` elementos. Delega el ranking en el RecommendationEngine
+// configurado en la plataforma.
+func (p *StreamingPlatform) GetFeed(userID string, limit int) ([]*Stream, error) {
+	user, err := p.userStore.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return p.recommender.Recommend(user, limit), nil
+}
+
+// SetHub conecta un StreamingHub a la plataforma para que AddContent y
+// UserWatchStream publiquen eventos en tiempo real hacia los clientes
+// suscritos. Es opcional: una plataforma sin hub sigue funcionando igual
+// que antes.
+func (p *StreamingPlatform) SetHub(hub *StreamingHub) {
+	p.hub = hub
 }
 
 // RegisterUser es una función de alto nivel para registrar un nuevo usuario.
@@ -352,15 +613,47 @@ func (p *StreamingPlatform) RegisterUser(username, subscription string) (*User,
 	return newUser, nil
 }
 
-// AddContent es una función de alto nivel para añadir nuevo contenido.
-func (p *StreamingPlatform) AddContent(title, genre, url string, duration int) (*Stream, error) {
+// AddContent es una función de alto nivel para añadir nuevo contenido. La
+// duración ya no se recibe manualmente: se obtiene analizando el archivo en
+// url con el MediaProbe configurado en la plataforma. Los archivos que el
+// probe no puede analizar, o cuyos metadatos validateMediaInfo considera
+// incompletos, se rechazan con ErrInvalidInput.
+func (p *StreamingPlatform) AddContent(title, genre, url string) (*Stream, error) {
+	if !isValidGenre(genre) {
+		return nil, fmt.Errorf("fallo al añadir contenido: %w: genero '%s' no valido", ErrInvalidInput, genre)
+	}
+
+	info, err := p.probe.Probe(url)
+	if err != nil {
+		return nil, fmt.Errorf("fallo al añadir contenido: %w", err)
+	}
+	if err := validateMediaInfo(info); err != nil {
+		return nil, fmt.Errorf("fallo al añadir contenido: %w", err)
+	}
+
 	newStreamID := generateNextID("stream", len(p.streamStore.GetAllStreams()))
-	newStream := NewStream(newStreamID, title, genre, url, duration)
-	err := p.streamStore.AddStream(newStream)
+	// Redondeamos hacia arriba (en vez de truncar) para que un clip de
+	// menos de un minuto no termine con duración 0 y sea rechazado por
+	// AddStream pese a tener metadatos válidos.
+	durationMin := int((info.DurationMs + 59999) / 60000)
+	newStream := NewStream(newStreamID, title, genre, url, durationMin)
+	newStream.setInfo(info)
+	newStream.setAvailableProfiles(defaultProfileLadder())
+	err = p.streamStore.AddStream(newStream)
 	if err != nil {
 		return nil, fmt.Errorf("fallo al añadir contenido: %w", err)
 	}
-	fmt.Printf("Contenido añadido: %s (ID: %s, Duración: %d min)\n", newStream.GetTitle(), newStream.GetID(), newStream.GetDurationMin())
+	fmt.Printf("Contenido añadido: %s (ID: %s, Duración: %d min, Codec: %s %dx%d)\n",
+		newStream.GetTitle(), newStream.GetID(), newStream.GetDurationMin(), info.Codec, info.Width, info.Height)
+
+	if p.hub != nil {
+		p.hub.Broadcast(Event{
+			Type:     EventNewContentAdded,
+			StreamID: newStream.GetID(),
+			Payload:  newStream,
+		})
+	}
+
 	return newStream, nil
 }
 
@@ -369,7 +662,10 @@ func (p *StreamingPlatform) GetContentDetails(streamID string) (*Stream, error)
 	return p.streamStore.GetStreamByID(streamID)
 }
 
-// UserWatchStream simula la acción de un usuario viendo un stream.
+// UserWatchStream simula la acción de un usuario viendo un stream. Aplica
+// las entitlements del nivel de suscripción del usuario: género permitido,
+// límite de streams concurrentes (vía SessionManager) y el perfil de
+// transcodificación más alto que su plan permite.
 func (p *StreamingPlatform) UserWatchStream(userID, streamID string) error {
 	user, err := p.userStore.GetUserByID(userID)
 	if err != nil {
@@ -380,7 +676,63 @@ func (p *StreamingPlatform) UserWatchStream(userID, streamID string) error {
 		return err // Retorna ErrStreamNotFound
 	}
 
-	return PlayStream(user, stream)
+	entitlements := EntitlementsFor(user.GetSubscriptionTier())
+	if !entitlements.allowsGenre(stream.GetGenre()) {
+		return fmt.Errorf("%w: el plan %s no incluye el género '%s'", ErrUnauthorized, user.GetSubscriptionTier(), stream.GetGenre())
+	}
+
+	if err := p.sessions.Start(userID, entitlements.MaxConcurrentStreams); err != nil {
+		return err
+	}
+	defer p.sessions.End(userID)
+
+	profile, err := selectProfile(stream.GetAvailableProfiles(), entitlements.MaxResolution)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Perfil de transcodificación seleccionado para %s: %s\n", user.GetUsername(), profile.Name)
+
+	if p.hub != nil {
+		p.hub.Publish(user.GetID(), Event{Type: EventPlaybackStarted, UserID: user.GetID(), StreamID: streamID})
+	}
+
+	if err := playStreamWithHub(user, stream, p.hub); err != nil {
+		return err
+	}
+	p.streamStore.RecordView(streamID)
+	if err := p.userStore.UpdateUser(user); err != nil {
+		return fmt.Errorf("fallo al persistir el historial de %s: %w", user.GetUsername(), err)
+	}
+	if p.federation != nil {
+		p.federation.Publish(user, stream)
+	}
+
+	if p.hub != nil {
+		p.hub.Publish(user.GetID(), Event{Type: EventPlaybackFinished, UserID: user.GetID(), StreamID: streamID})
+		p.hub.Publish(user.GetID(), Event{Type: EventWatchHistoryUpdate, UserID: user.GetID(), Payload: user.GetWatchHistory()})
+	}
+
+	return nil
+}
+
+// UpgradeSubscription cambia el nivel de suscripción de un usuario. Un
+// cambio a mitad de una reproducción no interrumpe el stream en curso (la
+// simulación es síncrona): las nuevas entitlements se aplican desde la
+// siguiente llamada a UserWatchStream.
+func (p *StreamingPlatform) UpgradeSubscription(userID string, tier SubscriptionTier) error {
+	if !isValidSubscriptionTier(tier) {
+		return fmt.Errorf("%w: nivel de suscripción '%s' no válido", ErrInvalidInput, tier)
+	}
+	user, err := p.userStore.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	user.SetSubscriptionTier(tier)
+	if err := p.userStore.UpdateUser(user); err != nil {
+		return fmt.Errorf("fallo al actualizar suscripción de %s: %w", user.GetUsername(), err)
+	}
+	fmt.Printf("Suscripción de %s actualizada a %s\n", user.GetUsername(), tier)
+	return nil
 }
 
 // =========================================================================
@@ -415,21 +767,32 @@ func main() {
 	// Por simplicidad, este ejemplo solo usa el generador secuencial.
 
 	// b) Añadir Contenido
+	// En este demo no hay archivos reales ni un binario ffprobe disponible,
+	// así que sustituimos el MediaProbe por defecto (FFProbe) por un
+	// MapProbe con metadatos fijos para las URLs de ejemplo.
+	platform.SetProbe(MapProbe{
+		"http://stream.com/inception": {Codec: "h264", Container: "mp4", BitrateKbps: 5000, Width: 1920, Height: 1080, DurationMs: 148 * 60 * 1000},
+		"http://stream.com/bb-s1e1":   {Codec: "h264", Container: "mp4", BitrateKbps: 4000, Width: 1920, Height: 1080, DurationMs: 55 * 60 * 1000},
+		"http://stream.com/classical": {Codec: "aac", Container: "m4a", BitrateKbps: 256, Width: 0, Height: 0, DurationMs: 60 * 60 * 1000},
+	})
+
 	fmt.Println("\n--- Añadiendo Contenido ---")
-	movie1, err := platform.AddContent("Inception", "Accion", "http://stream.com/inception", 148)
+	movie1, err := platform.AddContent("Inception", "Accion", "http://stream.com/inception")
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 	}
-	series1, err := platform.AddContent("Breaking Bad S1E1", "Drama", "http://stream.com/bb-s1e1", 55)
+	series1, err := platform.AddContent("Breaking Bad S1E1", "Drama", "http://stream.com/bb-s1e1")
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 	}
-	_, err = platform.AddContent("Classical Mix", "Musical", "http://stream.com/classical", 60)
+	// "Classical Mix" es audio puro (sin pista de video): validateMediaInfo
+	// lo rechaza porque faltan ancho/alto, tal como rechazaría un archivo corrupto.
+	_, err = platform.AddContent("Classical Mix", "Musical", "http://stream.com/classical")
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 	}
 	// Intentar añadir contenido con género inválido
-	_, err = platform.AddContent("Unknown Movie", "Fantasy", "http://stream.com/unknown", 90)
+	_, err = platform.AddContent("Unknown Movie", "Fantasy", "http://stream.com/unknown")
 	if err != nil {
 		fmt.Printf("Error: %v\n", err) // Debería mostrar ErrInvalidInput
 	}
@@ -481,5 +844,26 @@ func main() {
 		fmt.Printf("Historial de %s: %v\n", user2.GetUsername(), user2.GetWatchHistory())
 	}
 
+	// f) Federación: publicar las reproducciones ya simuladas como Watch
+	// activities y seguir a un actor remoto.
+	fmt.Println("\n--- Federación ---")
+	federation := NewFederation("https://streaming.example.com")
+	platform.SetFederation(federation)
+	if user1 != nil && movie1 != nil {
+		// UserWatchStream ya corrió antes de configurar la federación, así
+		// que publicamos manualmente la Watch activity de esa reproducción
+		// para ilustrar el formato JSON-LD que viajaría al outbox.
+		activity := federation.Publish(user1, movie1)
+		jsonLD, _ := activity.MarshalJSONLD()
+		fmt.Printf("Activity publicada en el outbox de %s: %s\n", user1.GetUsername(), jsonLD)
+	}
+	if user2 != nil {
+		if err := platform.Follow(user2.GetID(), "https://otra-instancia.example.com/users/carol"); err != nil {
+			fmt.Printf("Error al seguir actor remoto: %v\n", err)
+		} else {
+			fmt.Printf("%s ahora sigue a: %v\n", user2.GetUsername(), federation.Following(user2.GetID()))
+		}
+	}
+
 	fmt.Println("\n--- Sistema de Gestión de Streaming Finalizado ---")
 }
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+// Un clip de menos de un minuto pero con metadatos válidos no debe
+// rechazarse por redondear su duración a 0 minutos.
+func TestAddContentAcceptsSubMinuteDuration(t *testing.T) {
+	streamStore := NewInMemoryStreamStore()
+	userStore := NewInMemoryUserStore()
+	platform := NewStreamingPlatform(streamStore, userStore)
+	platform.SetProbe(MapProbe{
+		"http://stream.test/short": {Codec: "h264", Container: "mp4", Width: 1920, Height: 1080, DurationMs: 30000},
+	})
+
+	stream, err := platform.AddContent("Clip Corto", "Comedia", "http://stream.test/short")
+	if err != nil {
+		t.Fatalf("AddContent no debió rechazar un clip corto con metadatos válidos: %v", err)
+	}
+	if stream.GetDurationMin() < 1 {
+		t.Fatalf("esperaba al menos 1 minuto de duración almacenada, obtuve %d", stream.GetDurationMin())
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import "fmt"
+
+// =========================================================================
+// 15. Módulo de Manifiestos de Streaming (`manifest` package / section)
+//    Genera el manifiesto HLS (.m3u8) o DASH (.mpd) que un reproductor
+//    necesita para elegir entre los perfiles de transcodificación
+//    disponibles de un Stream.
+// =========================================================================
+
+// ManifestFormat identifica el formato de manifiesto solicitado.
+type ManifestFormat string
+
+const (
+	ManifestHLS  ManifestFormat = "hls"
+	ManifestDASH ManifestFormat = "dash"
+)
+
+// profileWidth estima el ancho de un perfil asumiendo relación 16:9, ya que
+// Profile solo registra el alto.
+func profileWidth(p Profile) int {
+	return p.Height * 16 / 9
+}
+
+// profileBandwidth estima el bitrate objetivo (bps) de un perfil a partir
+// de su resolución, usado para el atributo BANDWIDTH del master playlist.
+func profileBandwidth(p Profile) int {
+	return p.Height * 1000
+}
+
+// mediaPlaylistPath es la ruta relativa de la playlist de un perfil
+// concreto dentro del stream, servida por separado de este manifiesto.
+func mediaPlaylistPath(stream *Stream, p Profile) string {
+	return fmt.Sprintf("%s/%s/playlist.m3u8", stream.GetID(), p.Name)
+}
+
+// GenerateHLSManifest construye el master playlist HLS de un stream,
+// con una entrada EXT-X-STREAM-INF por cada perfil disponible.
+func GenerateHLSManifest(stream *Stream, profiles []Profile) []byte {
+	out := "#EXTM3U\n#EXT-X-VERSION:3\n"
+	for _, p := range profiles {
+		out += fmt.Sprintf(
+			"#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s\n",
+			profileBandwidth(p), profileWidth(p), p.Height, mediaPlaylistPath(stream, p),
+		)
+	}
+	return []byte(out)
+}
+
+// GenerateDASHManifest construye un MPD mínimo con un AdaptationSet de
+// video y una Representation por cada perfil disponible.
+func GenerateDASHManifest(stream *Stream, profiles []Profile) []byte {
+	out := fmt.Sprintf(
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+			"<MPD xmlns=\"urn:mpeg:dash:schema:mpd:2011\" profiles=\"urn:mpeg:dash:profile:isoff-live:2011\" type=\"static\" mediaPresentationDuration=\"PT%dS\">\n"+
+			"  <Period>\n"+
+			"    <AdaptationSet mimeType=\"video/mp4\" segmentAlignment=\"true\">\n",
+		stream.GetInfo().DurationMs/1000,
+	)
+	for i, p := range profiles {
+		out += fmt.Sprintf(
+			"      <Representation id=\"%d\" bandwidth=\"%d\" width=\"%d\" height=\"%d\">\n"+
+				"        <BaseURL>%s/%s/</BaseURL>\n"+
+				"      </Representation>\n",
+			i, profileBandwidth(p), profileWidth(p), p.Height, stream.GetID(), p.Name,
+		)
+	}
+	out += "    </AdaptationSet>\n  </Period>\n</MPD>\n"
+	return []byte(out)
+}
+
+// GenerateManifest serializa el manifiesto de stream en el formato pedido.
+func GenerateManifest(stream *Stream, format ManifestFormat) ([]byte, error) {
+	profiles := stream.GetAvailableProfiles()
+	switch format {
+	case ManifestHLS:
+		return GenerateHLSManifest(stream, profiles), nil
+	case ManifestDASH:
+		return GenerateDASHManifest(stream, profiles), nil
+	default:
+		return nil, fmt.Errorf("%w: formato de manifiesto desconocido '%s'", ErrInvalidInput, format)
+	}
+}
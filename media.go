@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// =========================================================================
+// 14. Módulo de Metadatos Multimedia (`media` package / section)
+//    Analiza el archivo detrás de cada Stream para obtener sus metadatos
+//    técnicos reales, en vez de confiar en una duración provista a mano.
+// =========================================================================
+
+// MediaInfo son los metadatos técnicos de un archivo multimedia, obtenidos
+// mediante MediaProbe al dar de alta un Stream.
+type MediaInfo struct {
+	Codec       string
+	Container   string
+	BitrateKbps int
+	Width       int
+	Height      int
+	DurationMs  int64
+}
+
+// MediaProbe analiza el archivo ubicado en url y devuelve sus metadatos.
+type MediaProbe interface {
+	Probe(url string) (MediaInfo, error)
+}
+
+// validateMediaInfo rechaza metadatos incompletos o inconsistentes antes de
+// insertar el stream correspondiente.
+func validateMediaInfo(info MediaInfo) error {
+	if info.Codec == "" || info.Container == "" || info.Width <= 0 || info.Height <= 0 || info.DurationMs <= 0 {
+		return fmt.Errorf("%w: metadatos de medio incompletos o inválidos", ErrInvalidInput)
+	}
+	return nil
+}
+
+// =========================================================================
+// 14.1 FFProbe: implementación respaldada por el binario externo `ffprobe`
+// =========================================================================
+
+// FFProbe implementa MediaProbe invocando `ffprobe -show_format -show_streams`
+// y parseando su salida JSON.
+type FFProbe struct{}
+
+// NewFFProbe crea un FFProbe listo para usar.
+func NewFFProbe() *FFProbe {
+	return &FFProbe{}
+}
+
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+}
+
+// Probe implementa MediaProbe.
+func (FFProbe) Probe(url string) (MediaInfo, error) {
+	cmd := exec.Command("ffprobe", "-v", "quiet", "-print_format", "json", "-show_format", "-show_streams", url)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return MediaInfo{}, fmt.Errorf("%w: ffprobe falló al analizar '%s': %v", ErrInvalidInput, url, err)
+	}
+
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(stdout.Bytes(), &parsed); err != nil {
+		return MediaInfo{}, fmt.Errorf("%w: salida de ffprobe inválida para '%s': %v", ErrInvalidInput, url, err)
+	}
+
+	info := MediaInfo{Container: parsed.Format.FormatName}
+	for _, stream := range parsed.Streams {
+		if stream.CodecType == "video" {
+			info.Codec = stream.CodecName
+			info.Width = stream.Width
+			info.Height = stream.Height
+			break
+		}
+	}
+	if durationSec, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil {
+		info.DurationMs = int64(durationSec * 1000)
+	}
+	if bitrate, err := strconv.Atoi(parsed.Format.BitRate); err == nil {
+		info.BitrateKbps = bitrate / 1000
+	}
+	return info, nil
+}
+
+// =========================================================================
+// 14.2 MapProbe: doble inyectable para demos y pruebas
+// =========================================================================
+
+// MapProbe es una implementación de MediaProbe respaldada por un mapa en
+// memoria, útil para demos y pruebas donde no hay un binario ffprobe ni
+// archivos reales disponibles.
+type MapProbe map[string]MediaInfo
+
+// Probe implementa MediaProbe.
+func (m MapProbe) Probe(url string) (MediaInfo, error) {
+	info, ok := m[url]
+	if !ok {
+		return MediaInfo{}, fmt.Errorf("%w: no hay metadatos registrados para '%s'", ErrInvalidInput, url)
+	}
+	return info, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// =========================================================================
+// 11. Módulo de Recomendaciones (`recommend` / RecommendationEngine)
+//    Genera un feed personalizado a partir del historial de visualización
+//    de cada usuario, combinando afinidad por género, popularidad global
+//    y qué tan reciente es el contenido.
+// =========================================================================
+
+// Pesos del puntaje combinado. Ajustables según se calibre el sistema.
+const (
+	weightGenreAffinity  = 0.6 // alpha
+	weightPopularity     = 0.3 // beta
+	weightRecency        = 0.1 // gamma
+	recencyHalfLifeHours = 24 * 14
+)
+
+// RecommendationEngine define el contrato para generar un feed de streams
+// recomendados para un usuario.
+type RecommendationEngine interface {
+	// Recommend devuelve hasta `limit` streams ordenados de mayor a menor
+	// relevancia para el usuario dado.
+	Recommend(user *User, limit int) []*Stream
+}
+
+// CollaborativeRecommender es la implementación por defecto de
+// RecommendationEngine: un esquema de dos etapas inspirado en los feeds de
+// actividad (afinidad por género + señal de popularidad global + frescura).
+type CollaborativeRecommender struct {
+	store      StreamStore
+	federation *Federation // opcional: suma la popularidad reportada por otras instancias
+}
+
+// NewCollaborativeRecommender crea un recomendador respaldado por el
+// StreamStore indicado, de donde obtiene el catálogo y la popularidad.
+func NewCollaborativeRecommender(store StreamStore) *CollaborativeRecommender {
+	return &CollaborativeRecommender{store: store}
+}
+
+// SetFederation conecta una Federation para que la popularidad global use
+// también la señal de visualizaciones reportadas por otras instancias.
+func (r *CollaborativeRecommender) SetFederation(federation *Federation) {
+	r.federation = federation
+}
+
+// popularity combina la popularidad local con la señal entre instancias,
+// cuando hay una Federation configurada.
+func (r *CollaborativeRecommender) popularity(streamID string) float64 {
+	total := r.store.Popularity(streamID)
+	if r.federation != nil {
+		total += r.federation.CrossInstancePopularityForStream(streamID)
+	}
+	return float64(total)
+}
+
+// Recommend implementa RecommendationEngine.
+func (r *CollaborativeRecommender) Recommend(user *User, limit int) []*Stream {
+	if user == nil || limit <= 0 {
+		return nil
+	}
+
+	watched := make(map[string]bool)
+	genreCounts := make(map[string]int)
+	totalWatched := 0
+
+	for _, streamID := range user.GetWatchHistory() {
+		watched[streamID] = true
+		if s, err := r.store.GetStreamByID(streamID); err == nil {
+			genreCounts[s.GetGenre()]++
+			totalWatched++
+		}
+	}
+
+	type scored struct {
+		stream     *Stream
+		popularity float64
+		score      float64
+	}
+
+	candidates := make([]scored, 0)
+	maxPopularity := 0.0
+	for _, s := range r.store.GetAllStreams() {
+		if watched[s.GetID()] {
+			continue
+		}
+		pop := r.popularity(s.GetID())
+		if pop > maxPopularity {
+			maxPopularity = pop
+		}
+		candidates = append(candidates, scored{stream: s, popularity: pop})
+	}
+
+	for i := range candidates {
+		candidates[i].score = weightGenreAffinity*genreAffinity(genreCounts, totalWatched, candidates[i].stream.GetGenre()) +
+			weightPopularity*normalizedPopularity(candidates[i].popularity, maxPopularity) +
+			weightRecency*recencyBoost(candidates[i].stream.GetAddedAt())
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		// Desempate determinista por ID para que el orden sea estable en tests.
+		return candidates[i].stream.GetID() < candidates[j].stream.GetID()
+	})
+
+	if limit > len(candidates) {
+		limit = len(candidates)
+	}
+
+	feed := make([]*Stream, limit)
+	for i := 0; i < limit; i++ {
+		feed[i] = candidates[i].stream
+	}
+	return feed
+}
+
+// normalizedPopularity escala pop (un conteo de vistas sin cota) al rango
+// [0,1] dividiéndolo por max, la popularidad más alta entre los candidatos
+// actuales. Sin esto, pop domina a genreAffinity y recencyBoost -que sí
+// están en [0,1]- en cuanto algún stream acumula un par de vistas, y el feed
+// colapsa a un ranking de popularidad global en vez de uno personalizado.
+func normalizedPopularity(pop, max float64) float64 {
+	if max <= 0 {
+		return 0
+	}
+	return pop / max
+}
+
+// genreAffinity devuelve qué fracción del historial del usuario corresponde
+// al género indicado. Si el usuario no tiene historial, la afinidad es 0.
+func genreAffinity(genreCounts map[string]int, totalWatched int, genre string) float64 {
+	if totalWatched == 0 {
+		return 0
+	}
+	return float64(genreCounts[genre]) / float64(totalWatched)
+}
+
+// recencyBoost decae exponencialmente con la antigüedad del contenido,
+// usando recencyHalfLifeHours como vida media.
+func recencyBoost(addedAt time.Time) float64 {
+	hoursSinceAdded := time.Since(addedAt).Hours()
+	if hoursSinceAdded < 0 {
+		hoursSinceAdded = 0
+	}
+	lambda := math.Ln2 / recencyHalfLifeHours
+	return math.Exp(-lambda * hoursSinceAdded)
+}
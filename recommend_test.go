@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Cuando varios streams empatan en puntaje (mismo género, popularidad y
+// fecha de alta), Recommend debe desempatar siempre por ID ascendente, sin
+// importar en qué orden los devuelva el StreamStore subyacente.
+func TestRecommendStableOrderingOnTies(t *testing.T) {
+	store := NewInMemoryStreamStore()
+	// Suficientemente antiguo para que recencyBoost haga underflow a 0.0 de
+	// forma exacta para los tres streams, sin importar los nanosegundos que
+	// transcurran entre llamadas a time.Now() dentro de Recommend: así el
+	// empate en el puntaje es real y el desempate por ID es lo único que
+	// decide el orden.
+	fixedAddedAt := time.Now().AddDate(-100, 0, 0)
+
+	// Insertados deliberadamente fuera de orden alfabético: GetAllStreams
+	// itera un map, así que si el test pasara por casualidad de orden de
+	// inserción no probaría nada sobre el desempate real.
+	for _, id := range []string{"stream-z", "stream-a", "stream-m"} {
+		s := NewStream(id, "Título "+id, "Drama", "http://x/"+id, 30)
+		s.addedAt = fixedAddedAt
+		if err := store.AddStream(s); err != nil {
+			t.Fatalf("AddStream(%s): %v", id, err)
+		}
+	}
+
+	user := NewUser("user-1", "tester", string(TierPremium))
+	recommender := NewCollaborativeRecommender(store)
+
+	want := []string{"stream-a", "stream-m", "stream-z"}
+	for attempt := 0; attempt < 5; attempt++ {
+		feed := recommender.Recommend(user, 10)
+		if len(feed) != len(want) {
+			t.Fatalf("esperaba %d streams en el feed, obtuve %d", len(want), len(feed))
+		}
+		for i, s := range feed {
+			if s.GetID() != want[i] {
+				t.Fatalf("orden inestable en posición %d: esperaba %q, obtuve %q", i, want[i], s.GetID())
+			}
+		}
+	}
+}
+
+// La popularidad es un conteo de vistas sin cota, mientras que genreAffinity
+// y recencyBoost están en [0,1]; Recommend debe normalizarla para que un
+// stream muy visto de otro género no desplace a uno del género preferido
+// del usuario que aún no acumuló vistas.
+func TestRecommendNormalizesPopularityAgainstGenreAffinity(t *testing.T) {
+	store := NewInMemoryStreamStore()
+
+	watched := NewStream("stream-watched", "Ya visto", "Drama", "http://x/watched", 30)
+	if err := store.AddStream(watched); err != nil {
+		t.Fatalf("AddStream(watched): %v", err)
+	}
+
+	genreMatch := NewStream("stream-genre-match", "Mismo género, sin vistas", "Drama", "http://x/match", 30)
+	popular := NewStream("stream-popular", "Otro género, muy visto", "Comedia", "http://x/popular", 30)
+	for _, s := range []*Stream{genreMatch, popular} {
+		if err := store.AddStream(s); err != nil {
+			t.Fatalf("AddStream(%s): %v", s.GetID(), err)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		store.RecordView(popular.GetID())
+	}
+
+	user := NewUser("user-1", "tester", string(TierPremium))
+	user.AddToWatchHistory(watched.GetID())
+
+	recommender := NewCollaborativeRecommender(store)
+	feed := recommender.Recommend(user, 10)
+	if len(feed) != 2 {
+		t.Fatalf("esperaba 2 streams en el feed, obtuve %d", len(feed))
+	}
+	if feed[0].GetID() != genreMatch.GetID() {
+		t.Fatalf("esperaba que la afinidad de género primara sobre la popularidad sin normalizar, obtuve primero %q", feed[0].GetID())
+	}
+}
@@ -0,0 +1,725 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// =========================================================================
+// 12. Módulo de Almacenamiento Persistente (`storage` package / section)
+//    InMemoryStreamStore/InMemoryUserStore siguen siendo el backend por
+//    defecto para pruebas y demos. Para despliegues reales, StorageConfig
+//    + Open() eligen entre un backend SQL (vía database/sql) o un backend
+//    de archivo JSON para nodos únicos. StreamingPlatform no conoce estos
+//    detalles: solo depende de las interfaces StreamStore/UserStore.
+// =========================================================================
+
+// StorageBackend identifica qué implementación concreta debe abrir Open.
+type StorageBackend string
+
+const (
+	BackendMemory StorageBackend = "memory"
+	BackendSQL    StorageBackend = "sql"
+	BackendJSON   StorageBackend = "json"
+)
+
+// StorageConfig describe cómo abrir el almacenamiento de la plataforma.
+type StorageConfig struct {
+	Backend StorageBackend // memory, sql o json
+	// DriverName y DSN se usan solo cuando Backend == BackendSQL, por
+	// ejemplo DriverName "sqlite3" y DSN "file:streaming.db".
+	DriverName string
+	DSN        string
+	// JSONDir se usa solo cuando Backend == BackendJSON: directorio donde
+	// se guardan streams.json y users.json.
+	JSONDir string
+}
+
+// Open construye un StreamStore y un UserStore según cfg, dejando la
+// plataforma agnóstica del backend concreto. Para BackendSQL aplica las
+// migraciones de esquema pendientes antes de devolver los stores.
+func Open(cfg StorageConfig) (StreamStore, UserStore, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewInMemoryStreamStore(), NewInMemoryUserStore(), nil
+
+	case BackendSQL:
+		driver := cfg.DriverName
+		if driver == "" {
+			driver = "sqlite3"
+		}
+		db, err := sql.Open(driver, cfg.DSN)
+		if err != nil {
+			return nil, nil, fmt.Errorf("storage: no se pudo abrir la base de datos: %w", err)
+		}
+		if err := runMigrations(db); err != nil {
+			return nil, nil, fmt.Errorf("storage: fallo al migrar el esquema: %w", err)
+		}
+		return &SQLStreamStore{db: db}, &SQLUserStore{db: db}, nil
+
+	case BackendJSON:
+		if cfg.JSONDir == "" {
+			return nil, nil, fmt.Errorf("%w: JSONDir es requerido para el backend json", ErrInvalidInput)
+		}
+		store, err := newJSONFileStore(cfg.JSONDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, store, nil
+
+	default:
+		return nil, nil, fmt.Errorf("%w: backend de almacenamiento desconocido '%s'", ErrInvalidInput, cfg.Backend)
+	}
+}
+
+// =========================================================================
+// 12.1 Migraciones de esquema versionadas
+// =========================================================================
+
+// migration es un paso de esquema numerado y aplicado a lo sumo una vez.
+type migration struct {
+	version int
+	stmt    string
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		stmt: `CREATE TABLE IF NOT EXISTS streams (
+			id           TEXT PRIMARY KEY,
+			title        TEXT NOT NULL,
+			genre        TEXT NOT NULL,
+			duration_min INTEGER NOT NULL,
+			url          TEXT NOT NULL,
+			added_at     DATETIME NOT NULL,
+			popularity   INTEGER NOT NULL DEFAULT 0
+		)`,
+	},
+	{
+		version: 2,
+		stmt: `CREATE TABLE IF NOT EXISTS users (
+			id           TEXT PRIMARY KEY,
+			username     TEXT NOT NULL,
+			subscription TEXT NOT NULL
+		)`,
+	},
+	{
+		version: 3,
+		stmt: `CREATE TABLE IF NOT EXISTS watch_history (
+			user_id   TEXT NOT NULL,
+			stream_id TEXT NOT NULL,
+			position  INTEGER NOT NULL
+		)`,
+	},
+	{
+		// info y profiles guardan, como JSON, los metadatos de MediaProbe y la
+		// escalera de perfiles de transcodificación de cada stream. Sin esto,
+		// un stream rehidratado desde SQL pierde su escalera de perfiles y
+		// selectProfile no tiene nada entre qué elegir.
+		version: 4,
+		stmt:    `ALTER TABLE streams ADD COLUMN info TEXT NOT NULL DEFAULT '{}'`,
+	},
+	{
+		version: 5,
+		stmt:    `ALTER TABLE streams ADD COLUMN profiles TEXT NOT NULL DEFAULT '[]'`,
+	},
+}
+
+// runMigrations crea la tabla de control schema_migrations si no existe y
+// aplica, en orden, las migraciones cuya versión aún no esté registrada.
+func runMigrations(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return err
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return err
+		}
+		applied[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migración %d: %w", m.version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, m.version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migración %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =========================================================================
+// 12.2 Backend SQL (database/sql, por defecto SQLite)
+// =========================================================================
+
+// SQLStreamStore implementa StreamStore sobre una base de datos SQL.
+type SQLStreamStore struct {
+	db *sql.DB
+}
+
+func (s *SQLStreamStore) AddStream(stream *Stream) error {
+	if stream == nil || stream.GetTitle() == "" || stream.GetURL() == "" || stream.GetDurationMin() <= 0 {
+		return ErrInvalidInput
+	}
+	if !isValidGenre(stream.GetGenre()) {
+		return fmt.Errorf("%w: genero '%s' no valido", ErrInvalidInput, stream.GetGenre())
+	}
+	infoJSON, err := json.Marshal(stream.GetInfo())
+	if err != nil {
+		return fmt.Errorf("storage: fallo al serializar info de '%s': %w", stream.GetID(), err)
+	}
+	profilesJSON, err := json.Marshal(stream.GetAvailableProfiles())
+	if err != nil {
+		return fmt.Errorf("storage: fallo al serializar perfiles de '%s': %w", stream.GetID(), err)
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO streams (id, title, genre, duration_min, url, added_at, popularity, info, profiles) VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)`,
+		stream.GetID(), stream.GetTitle(), stream.GetGenre(), stream.GetDurationMin(), stream.GetURL(), stream.GetAddedAt(), infoJSON, profilesJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("storage: fallo al insertar stream '%s': %w", stream.GetID(), err)
+	}
+	return nil
+}
+
+// hydrateStream reconstruye un *Stream a partir de sus columnas, incluyendo
+// la info de MediaProbe y la escalera de perfiles serializadas como JSON.
+func hydrateStream(id, title, genre, url string, durationMin int, addedAt time.Time, infoJSON, profilesJSON string) (*Stream, error) {
+	var info MediaInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		return nil, fmt.Errorf("storage: info inválida para stream '%s': %w", id, err)
+	}
+	var profiles []Profile
+	if err := json.Unmarshal([]byte(profilesJSON), &profiles); err != nil {
+		return nil, fmt.Errorf("storage: perfiles inválidos para stream '%s': %w", id, err)
+	}
+
+	stream := NewStream(id, title, genre, url, durationMin)
+	stream.addedAt = addedAt
+	stream.setInfo(info)
+	stream.setAvailableProfiles(profiles)
+	return stream, nil
+}
+
+func (s *SQLStreamStore) scanStream(row *sql.Row) (*Stream, error) {
+	var id, title, genre, url string
+	var durationMin int
+	var addedAt time.Time
+	var infoJSON, profilesJSON string
+	if err := row.Scan(&id, &title, &genre, &durationMin, &url, &addedAt, &infoJSON, &profilesJSON); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrStreamNotFound
+		}
+		return nil, err
+	}
+	return hydrateStream(id, title, genre, url, durationMin, addedAt, infoJSON, profilesJSON)
+}
+
+func (s *SQLStreamStore) GetStreamByID(id string) (*Stream, error) {
+	row := s.db.QueryRow(`SELECT id, title, genre, duration_min, url, added_at, info, profiles FROM streams WHERE id = ?`, id)
+	return s.scanStream(row)
+}
+
+func (s *SQLStreamStore) GetAllStreams() []*Stream {
+	return s.ListStreams(0, -1)
+}
+
+func (s *SQLStreamStore) ListStreams(offset, limit int) []*Stream {
+	query := `SELECT id, title, genre, duration_min, url, added_at, info, profiles FROM streams ORDER BY id LIMIT ? OFFSET ?`
+	sqlLimit := limit
+	if sqlLimit < 0 {
+		sqlLimit = -1 // SQLite: LIMIT -1 significa "sin límite"
+	}
+	rows, err := s.db.Query(query, sqlLimit, offset)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	streams := make([]*Stream, 0)
+	for rows.Next() {
+		var id, title, genre, url string
+		var durationMin int
+		var addedAt time.Time
+		var infoJSON, profilesJSON string
+		if err := rows.Scan(&id, &title, &genre, &durationMin, &url, &addedAt, &infoJSON, &profilesJSON); err != nil {
+			return streams
+		}
+		stream, err := hydrateStream(id, title, genre, url, durationMin, addedAt, infoJSON, profilesJSON)
+		if err != nil {
+			continue
+		}
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+func (s *SQLStreamStore) RecordView(streamID string) {
+	s.db.Exec(`UPDATE streams SET popularity = popularity + 1 WHERE id = ?`, streamID)
+}
+
+func (s *SQLStreamStore) Popularity(streamID string) int {
+	var popularity int
+	row := s.db.QueryRow(`SELECT popularity FROM streams WHERE id = ?`, streamID)
+	if err := row.Scan(&popularity); err != nil {
+		return 0
+	}
+	return popularity
+}
+
+// SQLUserStore implementa UserStore sobre una base de datos SQL.
+type SQLUserStore struct {
+	db *sql.DB
+}
+
+func (us *SQLUserStore) AddUser(user *User) error {
+	if user == nil || user.GetUsername() == "" || user.GetSubscription() == "" {
+		return ErrInvalidInput
+	}
+	if !isValidSubscriptionTier(user.GetSubscriptionTier()) {
+		return fmt.Errorf("%w: nivel de suscripción '%s' no válido", ErrInvalidInput, user.GetSubscription())
+	}
+	_, err := us.db.Exec(
+		`INSERT INTO users (id, username, subscription) VALUES (?, ?, ?)`,
+		user.GetID(), user.GetUsername(), user.GetSubscription(),
+	)
+	if err != nil {
+		return fmt.Errorf("storage: fallo al insertar usuario '%s': %w", user.GetID(), err)
+	}
+	return nil
+}
+
+// UpdateUser sincroniza la suscripción y el historial de visualización de
+// un usuario ya existente con su fila y su tabla watch_history.
+func (us *SQLUserStore) UpdateUser(user *User) error {
+	if user == nil {
+		return ErrInvalidInput
+	}
+	res, err := us.db.Exec(`UPDATE users SET username = ?, subscription = ? WHERE id = ?`, user.GetUsername(), user.GetSubscription(), user.GetID())
+	if err != nil {
+		return fmt.Errorf("storage: fallo al actualizar usuario '%s': %w", user.GetID(), err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return ErrUserNotFound
+	}
+
+	if _, err := us.db.Exec(`DELETE FROM watch_history WHERE user_id = ?`, user.GetID()); err != nil {
+		return fmt.Errorf("storage: fallo al sincronizar historial de '%s': %w", user.GetID(), err)
+	}
+	for position, streamID := range user.GetWatchHistory() {
+		if _, err := us.db.Exec(`INSERT INTO watch_history (user_id, stream_id, position) VALUES (?, ?, ?)`, user.GetID(), streamID, position); err != nil {
+			return fmt.Errorf("storage: fallo al sincronizar historial de '%s': %w", user.GetID(), err)
+		}
+	}
+	return nil
+}
+
+func (us *SQLUserStore) hydrate(id, username, subscription string) (*User, error) {
+	user := NewUser(id, username, subscription)
+	rows, err := us.db.Query(`SELECT stream_id FROM watch_history WHERE user_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var streamID string
+		if err := rows.Scan(&streamID); err != nil {
+			return nil, err
+		}
+		user.AddToWatchHistory(streamID)
+	}
+	return user, nil
+}
+
+func (us *SQLUserStore) GetUserByID(id string) (*User, error) {
+	var username, subscription string
+	row := us.db.QueryRow(`SELECT username, subscription FROM users WHERE id = ?`, id)
+	if err := row.Scan(&username, &subscription); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return us.hydrate(id, username, subscription)
+}
+
+func (us *SQLUserStore) GetAllUsers() []*User {
+	return us.ListUsers(0, -1)
+}
+
+func (us *SQLUserStore) ListUsers(offset, limit int) []*User {
+	sqlLimit := limit
+	if sqlLimit < 0 {
+		sqlLimit = -1
+	}
+	rows, err := us.db.Query(`SELECT id, username, subscription FROM users ORDER BY id LIMIT ? OFFSET ?`, sqlLimit, offset)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	users := make([]*User, 0)
+	for rows.Next() {
+		var id, username, subscription string
+		if err := rows.Scan(&id, &username, &subscription); err != nil {
+			return users
+		}
+		user, err := us.hydrate(id, username, subscription)
+		if err != nil {
+			return users
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
+// =========================================================================
+// 12.3 Backend de archivo JSON, para despliegues de un solo nodo
+// =========================================================================
+
+// jsonStreamRecord y jsonUserRecord son la representación en disco de
+// Stream y User, ya que sus campos reales no están exportados.
+type jsonStreamRecord struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Genre       string    `json:"genre"`
+	DurationMin int       `json:"duration_min"`
+	URL         string    `json:"url"`
+	AddedAt     time.Time `json:"added_at"`
+	Popularity  int       `json:"popularity"`
+	Info        MediaInfo `json:"info"`
+	Profiles    []Profile `json:"profiles"`
+}
+
+type jsonUserRecord struct {
+	ID           string   `json:"id"`
+	Username     string   `json:"username"`
+	Subscription string   `json:"subscription"`
+	WatchHistory []string `json:"watch_history"`
+}
+
+// JSONFileStore persiste streams y usuarios como dos archivos JSON
+// (streams.json y users.json) dentro de un directorio, protegidos por un
+// mutex. Implementa tanto StreamStore como UserStore, pensado para
+// despliegues de un solo nodo donde una base de datos real sería excesiva.
+type JSONFileStore struct {
+	mu         sync.Mutex
+	streamPath string
+	userPath   string
+}
+
+// newJSONFileStore crea (si hace falta) el directorio de datos y devuelve
+// un JSONFileStore listo para usar como StreamStore y UserStore.
+func newJSONFileStore(dir string) (*JSONFileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: no se pudo crear el directorio '%s': %w", dir, err)
+	}
+	return &JSONFileStore{
+		streamPath: dir + "/streams.json",
+		userPath:   dir + "/users.json",
+	}, nil
+}
+
+func (j *JSONFileStore) loadStreams() (map[string]jsonStreamRecord, error) {
+	records := make(map[string]jsonStreamRecord)
+	data, err := os.ReadFile(j.streamPath)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return records, nil
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (j *JSONFileStore) saveStreams(records map[string]jsonStreamRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.streamPath, data, 0o644)
+}
+
+func (j *JSONFileStore) loadUsers() (map[string]jsonUserRecord, error) {
+	records := make(map[string]jsonUserRecord)
+	data, err := os.ReadFile(j.userPath)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return records, nil
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (j *JSONFileStore) saveUsers(records map[string]jsonUserRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.userPath, data, 0o644)
+}
+
+// --- StreamStore ---
+
+func (j *JSONFileStore) AddStream(stream *Stream) error {
+	if stream == nil || stream.GetTitle() == "" || stream.GetURL() == "" || stream.GetDurationMin() <= 0 {
+		return ErrInvalidInput
+	}
+	if !isValidGenre(stream.GetGenre()) {
+		return fmt.Errorf("%w: genero '%s' no valido", ErrInvalidInput, stream.GetGenre())
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadStreams()
+	if err != nil {
+		return err
+	}
+	if _, exists := records[stream.GetID()]; exists {
+		return fmt.Errorf("stream con ID '%s' ya existe", stream.GetID())
+	}
+	records[stream.GetID()] = jsonStreamRecord{
+		ID: stream.GetID(), Title: stream.GetTitle(), Genre: stream.GetGenre(),
+		DurationMin: stream.GetDurationMin(), URL: stream.GetURL(), AddedAt: stream.GetAddedAt(),
+		Info: stream.GetInfo(), Profiles: stream.GetAvailableProfiles(),
+	}
+	return j.saveStreams(records)
+}
+
+func (j *JSONFileStore) GetStreamByID(id string) (*Stream, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadStreams()
+	if err != nil {
+		return nil, err
+	}
+	rec, ok := records[id]
+	if !ok {
+		return nil, ErrStreamNotFound
+	}
+	return recordToStream(rec), nil
+}
+
+func (j *JSONFileStore) GetAllStreams() []*Stream {
+	return j.ListStreams(0, -1)
+}
+
+func (j *JSONFileStore) ListStreams(offset, limit int) []*Stream {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadStreams()
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	start, end := page(len(ids), offset, limit)
+	streams := make([]*Stream, 0, end-start)
+	for _, id := range ids[start:end] {
+		streams = append(streams, recordToStream(records[id]))
+	}
+	return streams
+}
+
+func (j *JSONFileStore) RecordView(streamID string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadStreams()
+	if err != nil {
+		return
+	}
+	rec, ok := records[streamID]
+	if !ok {
+		return
+	}
+	rec.Popularity++
+	records[streamID] = rec
+	j.saveStreams(records)
+}
+
+func (j *JSONFileStore) Popularity(streamID string) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadStreams()
+	if err != nil {
+		return 0
+	}
+	return records[streamID].Popularity
+}
+
+// --- UserStore ---
+
+func (j *JSONFileStore) AddUser(user *User) error {
+	if user == nil || user.GetUsername() == "" || user.GetSubscription() == "" {
+		return ErrInvalidInput
+	}
+	if !isValidSubscriptionTier(user.GetSubscriptionTier()) {
+		return fmt.Errorf("%w: nivel de suscripción '%s' no válido", ErrInvalidInput, user.GetSubscription())
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadUsers()
+	if err != nil {
+		return err
+	}
+	if _, exists := records[user.GetID()]; exists {
+		return fmt.Errorf("usuario con ID '%s' ya existe", user.GetID())
+	}
+	records[user.GetID()] = jsonUserRecord{
+		ID: user.GetID(), Username: user.GetUsername(), Subscription: user.GetSubscription(),
+		WatchHistory: user.GetWatchHistory(),
+	}
+	return j.saveUsers(records)
+}
+
+// UpdateUser sobrescribe la suscripción y el historial de visualización de
+// un usuario ya existente.
+func (j *JSONFileStore) UpdateUser(user *User) error {
+	if user == nil {
+		return ErrInvalidInput
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadUsers()
+	if err != nil {
+		return err
+	}
+	if _, exists := records[user.GetID()]; !exists {
+		return ErrUserNotFound
+	}
+	records[user.GetID()] = jsonUserRecord{
+		ID: user.GetID(), Username: user.GetUsername(), Subscription: user.GetSubscription(),
+		WatchHistory: user.GetWatchHistory(),
+	}
+	return j.saveUsers(records)
+}
+
+func (j *JSONFileStore) GetUserByID(id string) (*User, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadUsers()
+	if err != nil {
+		return nil, err
+	}
+	rec, ok := records[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return recordToUser(rec), nil
+}
+
+func (j *JSONFileStore) GetAllUsers() []*User {
+	return j.ListUsers(0, -1)
+}
+
+func (j *JSONFileStore) ListUsers(offset, limit int) []*User {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records, err := j.loadUsers()
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	start, end := page(len(ids), offset, limit)
+	users := make([]*User, 0, end-start)
+	for _, id := range ids[start:end] {
+		users = append(users, recordToUser(records[id]))
+	}
+	return users
+}
+
+// recordToStream y recordToUser reconstruyen los tipos de dominio a partir
+// de su representación en disco.
+func recordToStream(rec jsonStreamRecord) *Stream {
+	stream := NewStream(rec.ID, rec.Title, rec.Genre, rec.URL, rec.DurationMin)
+	stream.addedAt = rec.AddedAt
+	stream.setInfo(rec.Info)
+	stream.setAvailableProfiles(rec.Profiles)
+	return stream
+}
+
+func recordToUser(rec jsonUserRecord) *User {
+	user := NewUser(rec.ID, rec.Username, rec.Subscription)
+	for _, streamID := range rec.WatchHistory {
+		user.AddToWatchHistory(streamID)
+	}
+	return user
+}
+
+// page calcula los índices [start, end) de una página de tamaño `limit` a
+// partir de `offset` sobre una colección de tamaño total. limit < 0 significa
+// "sin límite". Usado por ambos ListStreams y ListUsers.
+func page(total, offset, limit int) (start, end int) {
+	if offset >= total || offset < 0 {
+		return 0, 0
+	}
+	end = total
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return offset, end
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestStream crea un stream con perfiles e info de MediaProbe poblados,
+// tal como lo dejaría AddContent, para poder comparar el round-trip completo.
+func newTestStream(id string) *Stream {
+	s := NewStream(id, "Título "+id, "Drama", "http://x/"+id, 42)
+	s.setInfo(MediaInfo{Codec: "h264", Container: "mp4", BitrateKbps: 4000, Width: 1920, Height: 1080, DurationMs: 42 * 60 * 1000})
+	s.setAvailableProfiles(defaultProfileLadder())
+	return s
+}
+
+// assertStreamRoundTrip verifica que got conserve el género, duración, info
+// de MediaProbe y escalera de perfiles de want tras un ciclo de guardado y
+// recuperación. addedAt no se compara campo a campo: algunos backends (SQL)
+// pierden precisión de sub-segundo al pasar por el driver.
+func assertStreamRoundTrip(t *testing.T, got, want *Stream) {
+	t.Helper()
+	if got.GetGenre() != want.GetGenre() || got.GetDurationMin() != want.GetDurationMin() {
+		t.Fatalf("stream recuperado no coincide: got=%+v want=%+v", got, want)
+	}
+	if !reflect.DeepEqual(got.GetInfo(), want.GetInfo()) {
+		t.Fatalf("info no sobrevivió al round-trip: got=%+v want=%+v", got.GetInfo(), want.GetInfo())
+	}
+	if !reflect.DeepEqual(got.GetAvailableProfiles(), want.GetAvailableProfiles()) {
+		t.Fatalf("perfiles no sobrevivieron al round-trip: got=%+v want=%+v", got.GetAvailableProfiles(), want.GetAvailableProfiles())
+	}
+}
+
+func TestSQLStoresRoundTripStreamAndUser(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "streaming.db")
+	streamStore, userStore, err := Open(StorageConfig{Backend: BackendSQL, DriverName: "sqlite3", DSN: dsn})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	want := newTestStream("stream-1")
+	if err := streamStore.AddStream(want); err != nil {
+		t.Fatalf("AddStream: %v", err)
+	}
+	got, err := streamStore.GetStreamByID("stream-1")
+	if err != nil {
+		t.Fatalf("GetStreamByID: %v", err)
+	}
+	assertStreamRoundTrip(t, got, want)
+
+	user := NewUser("user-1", "alice", string(TierPremium))
+	if err := userStore.AddUser(user); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	user.AddToWatchHistory("stream-1")
+	user.AddToWatchHistory("stream-2")
+	if err := userStore.UpdateUser(user); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+
+	gotUser, err := userStore.GetUserByID("user-1")
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if want, got := []string{"stream-1", "stream-2"}, gotUser.GetWatchHistory(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("historial no sobrevivió al round-trip: esperaba %v, obtuve %v", want, got)
+	}
+}
+
+func TestJSONFileStoreRoundTripStreamAndUser(t *testing.T) {
+	streamStore, userStore, err := Open(StorageConfig{Backend: BackendJSON, JSONDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	want := newTestStream("stream-1")
+	if err := streamStore.AddStream(want); err != nil {
+		t.Fatalf("AddStream: %v", err)
+	}
+	got, err := streamStore.GetStreamByID("stream-1")
+	if err != nil {
+		t.Fatalf("GetStreamByID: %v", err)
+	}
+	assertStreamRoundTrip(t, got, want)
+
+	user := NewUser("user-1", "alice", string(TierPremium))
+	if err := userStore.AddUser(user); err != nil {
+		t.Fatalf("AddUser: %v", err)
+	}
+	user.AddToWatchHistory("stream-1")
+	user.AddToWatchHistory("stream-2")
+	if err := userStore.UpdateUser(user); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+
+	gotUser, err := userStore.GetUserByID("user-1")
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if want, got := []string{"stream-1", "stream-2"}, gotUser.GetWatchHistory(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("historial no sobrevivió al round-trip: esperaba %v, obtuve %v", want, got)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// =========================================================================
+// 10. Módulo de Streaming en Tiempo Real (`streaming` / StreamingHub)
+//    Añade a la simulación síncrona un modelo de publicación/
+//    suscripción: UserWatchStream y AddContent emiten eventos que el hub
+//    reparte a los clientes conectados por WebSocket.
+// =========================================================================
+
+// EventType identifica la clase de evento emitido por la plataforma.
+type EventType string
+
+const (
+	EventPlaybackStarted    EventType = "playback_started"
+	EventPlaybackProgress   EventType = "playback_progress"
+	EventPlaybackFinished   EventType = "playback_finished"
+	EventWatchHistoryUpdate EventType = "watch_history_updated"
+	EventNewContentAdded    EventType = "new_content_added"
+)
+
+// Event es el mensaje que viaja del hub hacia los sockets de los clientes.
+type Event struct {
+	Type      EventType   `json:"type"`
+	UserID    string      `json:"user_id,omitempty"`
+	StreamID  string      `json:"stream_id,omitempty"`
+	Payload   interface{} `json:"payload,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// subscriberBufferSize es el tamaño del canal por suscriptor. Al llenarse,
+// se descarta el evento más antiguo para no bloquear al publicador.
+const subscriberBufferSize = 32
+
+const (
+	heartbeatInterval = 20 * time.Second
+	pongWait          = 60 * time.Second
+	writeWait         = 10 * time.Second
+)
+
+// subscriber representa una conexión WebSocket asociada a un userID.
+type subscriber struct {
+	userID string
+	conn   *websocket.Conn
+	send   chan Event
+	done   chan struct{}
+}
+
+// StreamingHub reparte eventos de la plataforma a clientes conectados
+// mediante WebSockets, con un canal acotado por suscriptor (drop-oldest).
+type StreamingHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*subscriber]struct{} // userID -> conjunto de conexiones
+	upgrader    websocket.Upgrader
+
+	closing chan struct{}
+	closed  bool
+}
+
+// NewStreamingHub crea un hub listo para aceptar conexiones y publicar eventos.
+func NewStreamingHub() *StreamingHub {
+	return &StreamingHub{
+		subscribers: make(map[string]map[*subscriber]struct{}),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     func(r *http.Request) bool { return true },
+		},
+		closing: make(chan struct{}),
+	}
+}
+
+// Publish entrega un evento a todos los suscriptores del userID indicado.
+// Si el canal de un suscriptor está lleno, se descarta el evento más
+// antiguo para que los consumidores lentos no bloqueen al publicador.
+func (h *StreamingHub) Publish(userID string, evt Event) {
+	evt.Timestamp = time.Now()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers[userID] {
+		select {
+		case sub.send <- evt:
+		default:
+			// Canal lleno: descartamos el evento más viejo y reintentamos.
+			select {
+			case <-sub.send:
+			default:
+			}
+			select {
+			case sub.send <- evt:
+			default:
+			}
+		}
+	}
+}
+
+// Broadcast entrega un evento a todos los usuarios conectados, usado para
+// eventos que no pertenecen a un usuario concreto (ej. new_content_added).
+func (h *StreamingHub) Broadcast(evt Event) {
+	evt.Timestamp = time.Now()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			select {
+			case sub.send <- evt:
+			default:
+				select {
+				case <-sub.send:
+				default:
+				}
+				select {
+				case sub.send <- evt:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// ServeWS atiende una petición HTTP de upgrade a WebSocket. El cliente debe
+// identificarse con un userID y un accessToken; la verificación real del
+// token se delega en authenticate, inyectable para pruebas.
+func (h *StreamingHub) ServeWS(w http.ResponseWriter, r *http.Request, userID, accessToken string, authenticate func(userID, accessToken string) bool) {
+	if authenticate != nil && !authenticate(userID, accessToken) {
+		http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("streaming: fallo al actualizar a WebSocket: %v", err)
+		return
+	}
+
+	sub := &subscriber{
+		userID: userID,
+		conn:   conn,
+		send:   make(chan Event, subscriberBufferSize),
+		done:   make(chan struct{}),
+	}
+	h.addSubscriber(sub)
+
+	go h.writePump(sub)
+	go h.readPump(sub)
+}
+
+func (h *StreamingHub) addSubscriber(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[sub.userID] == nil {
+		h.subscribers[sub.userID] = make(map[*subscriber]struct{})
+	}
+	h.subscribers[sub.userID][sub] = struct{}{}
+}
+
+func (h *StreamingHub) removeSubscriber(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if subs, ok := h.subscribers[sub.userID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subscribers, sub.userID)
+		}
+	}
+}
+
+// writePump envía eventos encolados y mantiene el keepalive con pings
+// periódicos según heartbeatInterval.
+func (h *StreamingHub) writePump(sub *subscriber) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer func() {
+		ticker.Stop()
+		sub.conn.Close()
+	}()
+
+	for {
+		select {
+		case evt, ok := <-sub.send:
+			sub.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				sub.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := sub.conn.WriteJSON(evt); err != nil {
+				return
+			}
+		case <-ticker.C:
+			sub.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := sub.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-sub.done:
+			return
+		case <-h.closing:
+			sub.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		}
+	}
+}
+
+// readPump descarta mensajes entrantes pero procesa pong/close para
+// detectar clientes caídos y liberar recursos.
+func (h *StreamingHub) readPump(sub *subscriber) {
+	defer func() {
+		h.removeSubscriber(sub)
+		close(sub.done)
+		sub.conn.Close()
+	}()
+
+	sub.conn.SetReadDeadline(time.Now().Add(pongWait))
+	sub.conn.SetPongHandler(func(string) error {
+		sub.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := sub.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// Shutdown cierra todas las conexiones activas de forma ordenada.
+func (h *StreamingHub) Shutdown() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	close(h.closing)
+}
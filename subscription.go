@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// =========================================================================
+// 13. Módulo de Suscripciones (`subscription` package / section)
+//    Reemplaza el campo libre `subscription string` por un nivel tipado
+//    con entitlements asociadas: streams concurrentes, resolución máxima,
+//    géneros permitidos y la escalera de perfiles de transcodificación.
+// =========================================================================
+
+// SubscriptionTier identifica el plan contratado por un usuario.
+type SubscriptionTier string
+
+const (
+	TierFree    SubscriptionTier = "Free"
+	TierBasic   SubscriptionTier = "Basic"
+	TierPremium SubscriptionTier = "Premium"
+)
+
+// isValidSubscriptionTier indica si tier es uno de los planes soportados.
+func isValidSubscriptionTier(tier SubscriptionTier) bool {
+	_, ok := tierEntitlements[tier]
+	return ok
+}
+
+// Profile es un perfil de transcodificación (resolución objetivo).
+type Profile struct {
+	Name   string
+	Height int // alto en píxeles, usado para comparar perfiles entre sí
+}
+
+var (
+	Profile240p  = Profile{Name: "240p", Height: 240}
+	Profile480p  = Profile{Name: "480p", Height: 480}
+	Profile720p  = Profile{Name: "720p", Height: 720}
+	Profile1080p = Profile{Name: "1080p", Height: 1080}
+)
+
+// defaultProfileLadder es la escalera de perfiles asignada por defecto a
+// todo contenido nuevo dado de alta vía AddContent.
+func defaultProfileLadder() []Profile {
+	return []Profile{Profile240p, Profile480p, Profile720p, Profile1080p}
+}
+
+// Entitlements agrupa los derechos asociados a un SubscriptionTier.
+type Entitlements struct {
+	MaxConcurrentStreams int
+	MaxResolution        Profile
+	// AllowedGenres restringe los géneros reproducibles; nil significa que
+	// el plan no tiene restricción de género.
+	AllowedGenres []string
+}
+
+// allowsGenre indica si el plan permite reproducir el género indicado.
+func (e Entitlements) allowsGenre(genre string) bool {
+	if e.AllowedGenres == nil {
+		return true
+	}
+	for _, allowed := range e.AllowedGenres {
+		if allowed == genre {
+			return true
+		}
+	}
+	return false
+}
+
+// tierEntitlements define, por plan, el límite de streams concurrentes, la
+// resolución máxima de transcodificación y los géneros permitidos. Premium
+// no restringe género (AllowedGenres nil); Free y Basic acotan el catálogo
+// a géneros de menor costo de licenciamiento.
+var tierEntitlements = map[SubscriptionTier]Entitlements{
+	TierFree:    {MaxConcurrentStreams: 1, MaxResolution: Profile480p, AllowedGenres: []string{"Comedia", "Documental"}},
+	TierBasic:   {MaxConcurrentStreams: 2, MaxResolution: Profile720p, AllowedGenres: []string{"Accion", "Comedia", "Drama", "Documental", "Animacion"}},
+	TierPremium: {MaxConcurrentStreams: 4, MaxResolution: Profile1080p},
+}
+
+// EntitlementsFor devuelve las entitlements del plan indicado. Un tier
+// desconocido se trata como TierFree, el plan más restrictivo.
+func EntitlementsFor(tier SubscriptionTier) Entitlements {
+	if e, ok := tierEntitlements[tier]; ok {
+		return e
+	}
+	return tierEntitlements[TierFree]
+}
+
+// selectProfile elige el perfil de mayor resolución entre available que no
+// supere max. Devuelve ErrUnauthorized si ninguno cumple la condición.
+func selectProfile(available []Profile, max Profile) (Profile, error) {
+	best := Profile{}
+	found := false
+	for _, p := range available {
+		if p.Height <= max.Height && (!found || p.Height > best.Height) {
+			best = p
+			found = true
+		}
+	}
+	if !found {
+		return Profile{}, ErrUnauthorized
+	}
+	return best, nil
+}
+
+// =========================================================================
+// 13.1 SessionManager: límite de streams concurrentes por usuario
+// =========================================================================
+
+// SessionManager cuenta cuántos streams tiene activos cada usuario, para
+// hacer cumplir el límite de concurrencia de su plan.
+type SessionManager struct {
+	mu     sync.Mutex
+	active map[string]int // userID -> streams concurrentes en curso
+}
+
+// NewSessionManager crea un SessionManager vacío.
+func NewSessionManager() *SessionManager {
+	return &SessionManager{active: make(map[string]int)}
+}
+
+// Start registra el inicio de una nueva reproducción para userID. Devuelve
+// ErrUnauthorized si ya tiene `max` o más streams concurrentes activos.
+func (sm *SessionManager) Start(userID string, max int) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.active[userID] >= max {
+		return fmt.Errorf("%w: se alcanzó el límite de %d streams concurrentes", ErrUnauthorized, max)
+	}
+	sm.active[userID]++
+	return nil
+}
+
+// End libera una sesión de reproducción de userID.
+func (sm *SessionManager) End(userID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.active[userID] > 0 {
+		sm.active[userID]--
+	}
+	if sm.active[userID] == 0 {
+		delete(sm.active, userID)
+	}
+}
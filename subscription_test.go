@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// UpgradeSubscription documenta que un downgrade a mitad de una reproducción
+// no debe interrumpir el stream en curso, y que las nuevas entitlements solo
+// aplican desde la siguiente llamada a UserWatchStream. Este test cubre
+// justamente ese caso.
+func TestUpgradeSubscriptionDuringPlaybackDoesNotInterruptCurrentStream(t *testing.T) {
+	streamStore := NewInMemoryStreamStore()
+	userStore := NewInMemoryUserStore()
+	platform := NewStreamingPlatform(streamStore, userStore)
+	platform.SetProbe(MapProbe{
+		"http://stream.test/movie": {Codec: "h264", Container: "mp4", Width: 1920, Height: 1080, DurationMs: 60000},
+	})
+
+	user, err := platform.RegisterUser("alice", string(TierPremium))
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	// Género permitido tanto en Premium como en Free, para aislar el efecto
+	// del downgrade sobre la concurrencia/resolución del límite de género.
+	movie, err := platform.AddContent("Test Movie", "Comedia", "http://stream.test/movie")
+	if err != nil {
+		t.Fatalf("AddContent: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var watchErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchErr = platform.UserWatchStream(user.GetID(), movie.GetID())
+	}()
+
+	// Da tiempo a que UserWatchStream ya haya evaluado las entitlements de
+	// Premium e iniciado la sesión antes de degradar el plan.
+	time.Sleep(100 * time.Millisecond)
+
+	if err := platform.UpgradeSubscription(user.GetID(), TierFree); err != nil {
+		t.Fatalf("UpgradeSubscription no debió fallar a mitad de la reproducción: %v", err)
+	}
+
+	wg.Wait()
+	if watchErr != nil {
+		t.Fatalf("la reproducción ya en curso no debió verse interrumpida por el downgrade: %v", watchErr)
+	}
+
+	if got := user.GetSubscriptionTier(); got != TierFree {
+		t.Fatalf("esperaba nivel %q tras el downgrade, obtuve %q", TierFree, got)
+	}
+
+	// Una nueva reproducción, iniciada después del downgrade, ya debe
+	// evaluarse con las entitlements de Free.
+	if err := platform.UserWatchStream(user.GetID(), movie.GetID()); err != nil {
+		t.Fatalf("la reproducción posterior al downgrade debió permitirse bajo Free: %v", err)
+	}
+}